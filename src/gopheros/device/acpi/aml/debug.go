@@ -0,0 +1,99 @@
+package aml
+
+import (
+	"fmt"
+	"io"
+)
+
+// DebugLevel filters which stores to the AML Debug object are forwarded to
+// the configured debug writer, letting firmware-side tracing spam be
+// suppressed in production builds while still being available during
+// driver bring-up.
+type DebugLevel uint8
+
+// The supported debug levels, in increasing order of verbosity.
+const (
+	// DebugLevelOff discards all stores to the Debug object.
+	DebugLevelOff DebugLevel = iota
+	// DebugLevelInfo forwards stores to the Debug object to the debug
+	// writer. This is the default level.
+	DebugLevelInfo
+)
+
+// debugEntity is the singleton that backs the reserved Debug object. Stores
+// to Debug are detected by the store destination's type (*debugEntity)
+// rather than by name lookup.
+type debugEntity struct {
+	namedEntity
+}
+
+// SetDebugWriter installs w as the sink for stores to the AML Debug object,
+// replacing the default (vm.errWriter). Passing nil restores the default.
+func (vm *VM) SetDebugWriter(w io.Writer) {
+	vm.debugWriter = w
+}
+
+// SetDebugLevel controls how much Debug object traffic is forwarded to the
+// debug writer; DebugLevelOff silences it entirely.
+func (vm *VM) SetDebugLevel(level DebugLevel) {
+	vm.debugLevel = level
+}
+
+// vmStoreDebug formats src per ACPI §19.6.28 and writes it to the VM's
+// configured debug sink. Integers are rendered as hex using the current
+// sizeOfIntInBits width, Strings are written verbatim, Buffers are hex
+// dumped and Packages are rendered recursively.
+func (vm *VM) vmStoreDebug(ctx *execContext, src interface{}) {
+	if vm.debugLevel == DebugLevelOff {
+		return
+	}
+
+	w := vm.debugWriter
+	if w == nil {
+		w = vm.errWriter
+	}
+	if w == nil {
+		return
+	}
+
+	fmt.Fprintf(w, "[debug] %s\n", vmFormatDebugValue(ctx, src))
+}
+
+// vmFormatDebugValue renders v following the ACPI-mandated formatting rules
+// for the Debug object.
+func vmFormatDebugValue(ctx *execContext, v interface{}) string {
+	if ref, ok := v.(*objRef); ok {
+		v = ref.ref
+	}
+
+	switch val := v.(type) {
+	case uint64:
+		width := ctx.vm.sizeOfIntInBits
+		if width == 0 {
+			width = 64
+		}
+		return fmt.Sprintf("0x%0*x", width/4, val)
+	case string:
+		return val
+	case []byte:
+		out := make([]byte, 0, len(val)*3)
+		for i, b := range val {
+			if i > 0 {
+				out = append(out, ' ')
+			}
+			out = append(out, []byte(fmt.Sprintf("%02x", b))...)
+		}
+		return string(out)
+	case []interface{}:
+		formatted := "{"
+		for i, elem := range val {
+			if i > 0 {
+				formatted += ", "
+			}
+			formatted += vmFormatDebugValue(ctx, elem)
+		}
+		return formatted + "}"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
@@ -0,0 +1,258 @@
+package aml
+
+// regionEntity is the runtime representation of a DefOpRegion declaration.
+type regionEntity struct {
+	namedEntity
+
+	space  RegionSpace
+	offset uint64
+	length uint64
+}
+
+// fieldUnitEntity is the runtime representation of a single named field
+// declared inside a DefField, DefIndexField or DefBankField block. It
+// captures everything needed to translate a reference to the field into a
+// (space, offset, bitWidth) tuple that can be handed off to a
+// RegionHandler.
+type fieldUnitEntity struct {
+	namedEntity
+
+	region    *regionEntity
+	bitOffset uint64
+	bitWidth  uint64
+}
+
+// Read evaluates f by resolving its parent OperationRegion's address space
+// and routing the access through the RegionHandler registered for that
+// space.
+func (f *fieldUnitEntity) Read(ctx *execContext) (uint64, *Error) {
+	if f.region == nil {
+		return 0, errFieldParentNotRegion
+	}
+	if err := f.checkBounds(); err != nil {
+		return 0, err
+	}
+
+	return ctx.vm.readField(ctx, f.region.space, f.region.offset*8+f.bitOffset, f.bitWidth)
+}
+
+// Write stores val into f by resolving its parent OperationRegion's address
+// space and routing the access through the RegionHandler registered for
+// that space.
+func (f *fieldUnitEntity) Write(ctx *execContext, val uint64) *Error {
+	if f.region == nil {
+		return errFieldParentNotRegion
+	}
+	if err := f.checkBounds(); err != nil {
+		return err
+	}
+
+	return ctx.vm.writeField(ctx, f.region.space, f.region.offset*8+f.bitOffset, f.bitWidth, val)
+}
+
+// checkBounds verifies that f does not read or write past the end of its
+// parent OperationRegion, as declared by the region's Length operand.
+func (f *fieldUnitEntity) checkBounds() *Error {
+	if f.bitOffset+f.bitWidth > f.region.length*8 {
+		return errFieldOutOfBounds
+	}
+	return nil
+}
+
+// indexFieldEntity is the runtime representation of a single named field
+// declared inside a DefIndexField block. Unlike a plain fieldUnitEntity,
+// accesses are not addressed directly: the byte offset is first written to
+// an index register, and the value is then read from or written to a
+// separate data register (p.264 of the spec).
+type indexFieldEntity struct {
+	namedEntity
+
+	indexReg   *fieldUnitEntity
+	dataReg    *fieldUnitEntity
+	byteOffset uint64
+	bitWidth   uint64
+}
+
+// Read implements the index/data register indirection: it selects
+// byteOffset via the index register, then reads the value back from the
+// data register.
+func (f *indexFieldEntity) Read(ctx *execContext) (uint64, *Error) {
+	if f.indexReg == nil || f.dataReg == nil {
+		return 0, errFieldParentNotRegion
+	}
+
+	if err := f.indexReg.Write(ctx, f.byteOffset); err != nil {
+		return 0, err
+	}
+	return f.dataReg.Read(ctx)
+}
+
+// Write implements the index/data register indirection: it selects
+// byteOffset via the index register, then writes val through the data
+// register.
+func (f *indexFieldEntity) Write(ctx *execContext, val uint64) *Error {
+	if f.indexReg == nil || f.dataReg == nil {
+		return errFieldParentNotRegion
+	}
+
+	if err := f.indexReg.Write(ctx, f.byteOffset); err != nil {
+		return err
+	}
+	return f.dataReg.Write(ctx, val)
+}
+
+// bankFieldEntity is the runtime representation of a single named field
+// declared inside a DefBankField block. It behaves exactly like a plain
+// fieldUnitEntity except that, before every access, bankValue is latched
+// into the bank selector register to switch the OperationRegion to the
+// correct bank (p.263 of the spec).
+type bankFieldEntity struct {
+	fieldUnitEntity
+
+	bankSelector *fieldUnitEntity
+	bankValue    uint64
+}
+
+// Read selects this field's bank before delegating to fieldUnitEntity.Read.
+func (f *bankFieldEntity) Read(ctx *execContext) (uint64, *Error) {
+	if f.bankSelector == nil {
+		return 0, errFieldParentNotRegion
+	}
+
+	if err := f.bankSelector.Write(ctx, f.bankValue); err != nil {
+		return 0, err
+	}
+	return f.fieldUnitEntity.Read(ctx)
+}
+
+// Write selects this field's bank before delegating to fieldUnitEntity.Write.
+func (f *bankFieldEntity) Write(ctx *execContext, val uint64) *Error {
+	if f.bankSelector == nil {
+		return errFieldParentNotRegion
+	}
+
+	if err := f.bankSelector.Write(ctx, f.bankValue); err != nil {
+		return err
+	}
+	return f.fieldUnitEntity.Write(ctx, val)
+}
+
+// vmOpField implements the opField opcode: it declares the named field
+// units contained in a DefField block, attaching each to its parent
+// OperationRegion so that later references to the field name can be routed
+// through the region's RegionHandler via fieldUnitEntity.Read/Write.
+func vmOpField(ctx *execContext, ent Entity) *Error {
+	scoped, ok := ent.(ScopeEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+
+	regionEnt := vmLookupFieldRegion(ctx, ent)
+	if regionEnt == nil {
+		return errFieldParentNotRegion
+	}
+
+	for _, child := range scoped.Children() {
+		f, ok := child.(*fieldUnitEntity)
+		if !ok || f.region != nil {
+			continue
+		}
+		f.region = regionEnt
+	}
+
+	return nil
+}
+
+// vmOpIndexField implements the opIndexField opcode: args[0] and args[1] of
+// the declaration name the index and data registers respectively. Each
+// *indexFieldEntity child is wired to that register pair so accesses route
+// through the index/data indirection instead of a flat byte offset.
+func vmOpIndexField(ctx *execContext, ent Entity) *Error {
+	scoped, ok := ent.(ScopeEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 2 {
+		return errFieldParentNotRegion
+	}
+
+	indexReg, ok := unwrapRef(namedEnt.args[0]).(*fieldUnitEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+	dataReg, ok := unwrapRef(namedEnt.args[1]).(*fieldUnitEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+
+	for _, child := range scoped.Children() {
+		f, ok := child.(*indexFieldEntity)
+		if !ok || f.indexReg != nil {
+			continue
+		}
+		f.indexReg = indexReg
+		f.dataReg = dataReg
+	}
+
+	return nil
+}
+
+// vmOpBankField implements the opBankField opcode: args[0] names the
+// OperationRegion, args[1] names the bank selector register and args[2] is
+// the bank value this block's fields should latch before every access. Each
+// *bankFieldEntity child is wired up accordingly.
+func vmOpBankField(ctx *execContext, ent Entity) *Error {
+	scoped, ok := ent.(ScopeEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 3 {
+		return errFieldParentNotRegion
+	}
+
+	regionEnt, ok := unwrapRef(namedEnt.args[0]).(*regionEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+	bankSelector, ok := unwrapRef(namedEnt.args[1]).(*fieldUnitEntity)
+	if !ok {
+		return errFieldParentNotRegion
+	}
+	bankValue, err := vmConvert(ctx, namedEnt.args[2], valueTypeInteger)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range scoped.Children() {
+		f, ok := child.(*bankFieldEntity)
+		if !ok || f.region != nil {
+			continue
+		}
+		f.region = regionEnt
+		f.bankSelector = bankSelector
+		f.bankValue = bankValue.(uint64)
+	}
+
+	return nil
+}
+
+// vmLookupFieldRegion resolves the OperationRegion that a DefField
+// declaration targets. The parser is expected to stash a reference to the
+// named region as the declaration's first argument, mirroring how other
+// named references (e.g. objRef) are boxed elsewhere in the interpreter.
+func vmLookupFieldRegion(ctx *execContext, ent Entity) *regionEntity {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) == 0 {
+		return nil
+	}
+
+	r, ok := unwrapRef(namedEnt.args[0]).(*regionEntity)
+	if !ok {
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,119 @@
+package aml
+
+var (
+	errTargetNotAMethod = &Error{message: "vm: InvokeMethod target is not a Method entity"}
+	errArgCountMismatch = &Error{message: "vm: InvokeMethod called with the wrong number of arguments"}
+	errNotAPackage      = &Error{message: "vm: EvaluatePackage target did not return a Package"}
+)
+
+// InvokeMethod looks up absPath, verifies that it resolves to a Method
+// entity and evaluates it with the supplied arguments, returning the
+// method's return value. Go arguments are boxed into their AML value
+// equivalents (Integer, String, Buffer or Package) before being passed to
+// the method; unsupported argument types result in an error. This is the
+// primary entry point for code outside the aml package (e.g. the device
+// manager) that needs to evaluate a named control method such as
+// "\_SB.PCI0._STA" or "\_PIC".
+func (vm *VM) InvokeMethod(absPath string, args ...interface{}) (interface{}, *Error) {
+	ent := vm.Lookup(absPath)
+	if ent == nil {
+		return nil, errLookupFailed
+	}
+
+	method, ok := ent.(*Method)
+	if !ok {
+		return nil, errTargetNotAMethod
+	}
+
+	if len(args) != method.argCount {
+		return nil, errArgCountMismatch
+	}
+
+	ctx := &execContext{vm: vm}
+	for argIndex, arg := range args {
+		boxed, err := vmBoxValue(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		ctx.methodArg[argIndex] = boxed
+	}
+
+	if vm.tracer != nil {
+		vm.tracer.OnMethodEnter(ctx, method)
+	}
+
+	err := vm.vmEnterMethod(ctx, method)
+	if err == nil {
+		err = vm.execBlock(ctx, method)
+
+		if exitErr := vm.vmExitMethod(ctx, method); err == nil {
+			err = exitErr
+		}
+	}
+
+	if vm.tracer != nil {
+		vm.tracer.OnMethodExit(ctx, method, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	retVal := ctx.retVal
+	if ref, ok := retVal.(*objRef); ok {
+		retVal = ref.ref
+	}
+
+	return retVal, nil
+}
+
+// EvaluateInteger invokes the method at absPath and coerces its return
+// value to a uint64, returning an error if the method does not return an
+// Integer-compatible value.
+func (vm *VM) EvaluateInteger(absPath string, args ...interface{}) (uint64, *Error) {
+	res, err := vm.InvokeMethod(absPath, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := &execContext{vm: vm}
+	converted, err := vmConvert(ctx, res, valueTypeInteger)
+	if err != nil {
+		return 0, err
+	}
+
+	return converted.(uint64), nil
+}
+
+// EvaluatePackage invokes the method at absPath and asserts that its return
+// value is a Package, returning the list of boxed package elements.
+func (vm *VM) EvaluatePackage(absPath string, args ...interface{}) ([]interface{}, *Error) {
+	res, err := vm.InvokeMethod(absPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, ok := res.([]interface{})
+	if !ok {
+		return nil, errNotAPackage
+	}
+
+	return pkg, nil
+}
+
+// vmBoxValue converts a plain Go value into the AML value representation
+// expected by execContext.methodArg/localArg slots.
+func vmBoxValue(ctx *execContext, v interface{}) (interface{}, *Error) {
+	switch val := v.(type) {
+	case Entity:
+		return &objRef{ref: val}, nil
+	case string, []byte, []interface{}:
+		return val, nil
+	case uint64:
+		return val, nil
+	case int:
+		return uint64(val), nil
+	default:
+		return vmConvert(ctx, v, valueTypeInteger)
+	}
+}
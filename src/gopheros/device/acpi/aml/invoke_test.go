@@ -0,0 +1,38 @@
+package aml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVMBoxValuePassesThroughSimpleTypes(t *testing.T) {
+	ctx := &execContext{vm: NewVM(nil, nil)}
+
+	cases := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"string", "hello", "hello"},
+		{"uint64", uint64(42), uint64(42)},
+		{"int", 7, uint64(7)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := vmBoxValue(ctx, c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != c.want {
+				t.Fatalf("vmBoxValue(%v) = %v, want %v", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestErrNotAPackageMessageIsSpecificToPackages(t *testing.T) {
+	if !strings.Contains(errNotAPackage.Error(), "Package") {
+		t.Fatalf("expected errNotAPackage's message to mention Package, got %q", errNotAPackage.Error())
+	}
+}
@@ -0,0 +1,20 @@
+package aml
+
+// populateJumpTable wires the opcodes implemented by this package to their
+// handler functions. It only fills in the slots for opcodes whose
+// execution logic lives in this package; any opcode left at its zero value
+// here is expected to be wired up by whichever file implements it.
+func (vm *VM) populateJumpTable() {
+	vm.jumpTable[opField] = vmOpField
+	vm.jumpTable[opIndexField] = vmOpIndexField
+	vm.jumpTable[opBankField] = vmOpBankField
+	vm.jumpTable[opNotify] = vmOpNotify
+	vm.jumpTable[opMutex] = vmOpMutex
+	vm.jumpTable[opEvent] = vmOpEvent
+	vm.jumpTable[opAcquire] = vmOpAcquire
+	vm.jumpTable[opRelease] = vmOpRelease
+	vm.jumpTable[opSignal] = vmOpSignal
+	vm.jumpTable[opWait] = vmOpWait
+	vm.jumpTable[opReset] = vmOpReset
+	vm.jumpTable[opStore] = vmOpStore
+}
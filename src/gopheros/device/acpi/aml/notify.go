@@ -0,0 +1,99 @@
+package aml
+
+var errNotifyTargetNotAnEntity = &Error{message: "vm: Notify target operand does not resolve to an entity"}
+
+// NotifyHandler is invoked whenever AML code executes a Notify() opcode
+// against an entity at or below the scope the handler was registered on.
+type NotifyHandler func(ent Entity, value uint64)
+
+// notifyBinding associates a NotifyHandler with the scope it was registered
+// against so that handler lookups can walk up the namespace tree and find
+// the closest applicable handler.
+type notifyBinding struct {
+	scope Entity
+	fn    NotifyHandler
+}
+
+// defaultNotifyHandler is invoked when a Notify() target has no registered
+// handler anywhere along its ancestor chain. It is a no-op so that firmware
+// notifications for devices the OS does not yet care about are silently
+// dropped instead of causing an interpreter error.
+func defaultNotifyHandler(_ Entity, _ uint64) {}
+
+// RegisterNotifyHandler installs fn as the handler for Notify() events
+// targeting scope or any entity nested below it. Registering a handler on
+// an ancestor scope (e.g. "\_TZ") lets a subsystem receive notifications
+// for every descendant (e.g. each thermal zone) without registering a
+// handler on each one individually. Registering a new handler for a scope
+// that already has one replaces it.
+func (vm *VM) RegisterNotifyHandler(scope Entity, fn NotifyHandler) {
+	for i, binding := range vm.notifyHandlers {
+		if binding.scope == scope {
+			vm.notifyHandlers[i].fn = fn
+			return
+		}
+	}
+
+	vm.notifyHandlers = append(vm.notifyHandlers, notifyBinding{scope: scope, fn: fn})
+}
+
+// notifyHandlerFor walks up the namespace tree starting at ent and returns
+// the closest registered NotifyHandler, falling back to
+// defaultNotifyHandler if none of ent's ancestors (including ent itself)
+// has one registered.
+func (vm *VM) notifyHandlerFor(ent Entity) NotifyHandler {
+	for cur := ent; cur != nil; cur = entityParent(cur) {
+		for _, binding := range vm.notifyHandlers {
+			if binding.scope == cur {
+				return binding.fn
+			}
+		}
+	}
+
+	return defaultNotifyHandler
+}
+
+// dispatchNotify resolves the handler registered for (or for the closest
+// ancestor of) target and invokes it asynchronously so that a slow handler
+// cannot stall the AML interpreter loop. The contract mirrors real
+// firmware: by the time Notify() returns to the caller, delivery is
+// guaranteed to have been scheduled but not necessarily completed.
+func (vm *VM) dispatchNotify(target Entity, value uint64) {
+	handler := vm.notifyHandlerFor(target)
+	go func() {
+		defer func() {
+			// A misbehaving notification handler (e.g. a future device
+			// manager callback) must not be able to take down the VM or
+			// the goroutine that is driving it.
+			recover()
+		}()
+		handler(target, value)
+	}()
+}
+
+// vmOpNotify implements the Notify() opcode: it resolves the target entity
+// and value operands and hands them off to dispatchNotify.
+func vmOpNotify(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 2 {
+		return errNotifyTargetNotAnEntity
+	}
+
+	target := namedEnt.args[0]
+	if ref, ok := target.(*objRef); ok {
+		target = ref.ref
+	}
+
+	targetEnt, ok := target.(Entity)
+	if !ok {
+		return errNotifyTargetNotAnEntity
+	}
+
+	value, err := vmConvert(ctx, namedEnt.args[1], valueTypeInteger)
+	if err != nil {
+		return err
+	}
+
+	ctx.vm.dispatchNotify(targetEnt, value.(uint64))
+	return nil
+}
@@ -0,0 +1,68 @@
+package aml
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEntityWithParent lets the test establish a namespace ancestry chain
+// without depending on the concrete (unexported) entity types that
+// construct it at parse time.
+type fakeEntityWithParent struct {
+	namedEntity
+	parent Entity
+}
+
+func (f *fakeEntityWithParent) Parent() Entity { return f.parent }
+
+func TestNotifyHandlerForWalksUpToClosestAncestor(t *testing.T) {
+	vm := NewVM(nil, nil)
+
+	grandparent := &namedEntity{name: "_TZ_"}
+	child := &fakeEntityWithParent{namedEntity: namedEntity{name: "TZ00"}, parent: grandparent}
+
+	var got uint64
+	var mu sync.Mutex
+	done := make(chan struct{})
+
+	vm.RegisterNotifyHandler(grandparent, func(_ Entity, value uint64) {
+		mu.Lock()
+		got = value
+		mu.Unlock()
+		close(done)
+	})
+
+	vm.dispatchNotify(child, 0x80)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the registered notify handler to run")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got != 0x80 {
+		t.Fatalf("expected notify value 0x80, got %#x", got)
+	}
+}
+
+func TestDispatchNotifyRecoversFromPanickingHandler(t *testing.T) {
+	vm := NewVM(nil, nil)
+	target := &namedEntity{name: "DEV0"}
+
+	done := make(chan struct{})
+	vm.RegisterNotifyHandler(target, func(_ Entity, _ uint64) {
+		defer close(done)
+		panic("boom")
+	})
+
+	vm.dispatchNotify(target, 1)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the panicking notify handler to run")
+	}
+}
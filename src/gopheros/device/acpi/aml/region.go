@@ -0,0 +1,120 @@
+package aml
+
+import (
+	"gopheros/kernel/hal/arch"
+)
+
+// RegionSpace identifies the address space that an ACPI OperationRegion
+// refers to. The numeric values match the OperationRegion SpaceID encoding
+// defined by the ACPI specification (see table 19.6.100).
+type RegionSpace uint8
+
+// The list of address spaces defined by the ACPI specification that an
+// OperationRegion may target.
+const (
+	RegionSpaceSystemMemory RegionSpace = iota
+	RegionSpaceSystemIO
+	RegionSpacePCIConfig
+	RegionSpaceEmbeddedControl
+	RegionSpaceSMBus
+	RegionSpaceSystemCMOS
+	RegionSpacePCIBarTarget
+	RegionSpaceIPMI
+	RegionSpaceGeneralPurposeIO
+	RegionSpaceGenericSerialBus
+)
+
+// RegionHandler is implemented by types that can service reads and writes
+// against a particular ACPI OperationRegion address space. The host kernel
+// is expected to register a handler for each address space it supports via
+// VM.RegisterRegionHandler; the interpreter itself only computes the field
+// unit's bit offset and bit width before delegating the actual access to
+// the handler. A field's access type and update rule are not applied.
+type RegionHandler interface {
+	// ReadRegion reads bitWidth bits starting at offset (both expressed in
+	// bits; offset counts from the start of the address space and includes
+	// the OperationRegion's own offset) and returns the result
+	// zero-extended to a uint64.
+	ReadRegion(regionSpace uint8, offset, bitWidth uint64) (uint64, *Error)
+
+	// WriteRegion writes the low bitWidth bits of val at offset (both
+	// expressed in bits; offset counts from the start of the address space
+	// and includes the OperationRegion's own offset).
+	WriteRegion(regionSpace uint8, offset, bitWidth uint64, val uint64) *Error
+}
+
+// RegisterRegionHandler installs h as the handler responsible for servicing
+// accesses to OperationRegions declared against the given address space,
+// replacing any previously registered handler for that space.
+func (vm *VM) RegisterRegionHandler(space RegionSpace, h RegionHandler) {
+	vm.regionHandlers[space] = h
+}
+
+// regionHandlerFor returns the handler registered for space, or nil if no
+// handler has been registered.
+func (vm *VM) regionHandlerFor(space RegionSpace) RegionHandler {
+	return vm.regionHandlers[space]
+}
+
+// readField services a read access to a field unit whose parent
+// OperationRegion lives in regionSpace, dispatching it to the registered
+// RegionHandler for that address space.
+func (vm *VM) readField(ctx *execContext, regionSpace RegionSpace, offset, bitWidth uint64) (uint64, *Error) {
+	if vm.tracer != nil {
+		vm.tracer.OnRegionAccess(ctx, regionSpace, offset, bitWidth, false)
+	}
+
+	h := vm.regionHandlerFor(regionSpace)
+	if h == nil {
+		return 0, errUnhandledRegionSpace
+	}
+
+	return h.ReadRegion(uint8(regionSpace), offset, bitWidth)
+}
+
+// writeField services a write access to a field unit whose parent
+// OperationRegion lives in regionSpace, dispatching it to the registered
+// RegionHandler for that address space.
+func (vm *VM) writeField(ctx *execContext, regionSpace RegionSpace, offset, bitWidth uint64, val uint64) *Error {
+	if vm.tracer != nil {
+		vm.tracer.OnRegionAccess(ctx, regionSpace, offset, bitWidth, true)
+	}
+
+	h := vm.regionHandlerFor(regionSpace)
+	if h == nil {
+		return errUnhandledRegionSpace
+	}
+
+	return h.WriteRegion(uint8(regionSpace), offset, bitWidth, val)
+}
+
+// defaultSystemMemoryHandler is the RegionHandler installed by NewVM for the
+// SystemMemory address space. It services accesses via the arch package so
+// that unit tests can exercise the field-access path without requiring a
+// platform-specific MMIO backend.
+type defaultSystemMemoryHandler struct{}
+
+// ReadRegion implements RegionHandler.
+func (defaultSystemMemoryHandler) ReadRegion(regionSpace uint8, offset, bitWidth uint64) (uint64, *Error) {
+	return arch.ReadPhysMemory(offset/8, bitWidth)
+}
+
+// WriteRegion implements RegionHandler.
+func (defaultSystemMemoryHandler) WriteRegion(regionSpace uint8, offset, bitWidth uint64, val uint64) *Error {
+	return arch.WritePhysMemory(offset/8, bitWidth, val)
+}
+
+// defaultSystemIOHandler is the RegionHandler installed by NewVM for the
+// SystemIO address space. It services accesses via the arch package's port
+// I/O primitives.
+type defaultSystemIOHandler struct{}
+
+// ReadRegion implements RegionHandler.
+func (defaultSystemIOHandler) ReadRegion(regionSpace uint8, offset, bitWidth uint64) (uint64, *Error) {
+	return arch.ReadIOPort(uint16(offset/8), bitWidth)
+}
+
+// WriteRegion implements RegionHandler.
+func (defaultSystemIOHandler) WriteRegion(regionSpace uint8, offset, bitWidth uint64, val uint64) *Error {
+	return arch.WriteIOPort(uint16(offset/8), bitWidth, val)
+}
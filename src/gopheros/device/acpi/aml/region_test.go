@@ -0,0 +1,188 @@
+package aml
+
+import "testing"
+
+// fakeRegionHandler is an in-memory RegionHandler used to exercise the
+// field-unit routing logic without depending on a real MMIO/port backend.
+type fakeRegionHandler struct {
+	mem map[uint64]uint64
+}
+
+func newFakeRegionHandler() *fakeRegionHandler {
+	return &fakeRegionHandler{mem: make(map[uint64]uint64)}
+}
+
+func (h *fakeRegionHandler) ReadRegion(_ uint8, offset, _ uint64) (uint64, *Error) {
+	return h.mem[offset], nil
+}
+
+func (h *fakeRegionHandler) WriteRegion(_ uint8, offset, _ uint64, val uint64) *Error {
+	h.mem[offset] = val
+	return nil
+}
+
+func TestFieldUnitReadWriteRoutesThroughRegionHandler(t *testing.T) {
+	vm := NewVM(nil, nil)
+	handler := newFakeRegionHandler()
+	vm.RegisterRegionHandler(RegionSpaceEmbeddedControl, handler)
+
+	region := &regionEntity{
+		namedEntity: namedEntity{name: "EC0_"},
+		space:       RegionSpaceEmbeddedControl,
+		offset:      4,
+		length:      2,
+	}
+	field := &fieldUnitEntity{
+		namedEntity: namedEntity{name: "TEMP"},
+		region:      region,
+		bitOffset:   8,
+		bitWidth:    8,
+	}
+
+	ctx := &execContext{vm: vm}
+
+	if err := field.Write(ctx, 0x2a); err != nil {
+		t.Fatalf("unexpected error writing field: %s", err)
+	}
+
+	got, err := field.Read(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error reading field: %s", err)
+	}
+	if got != 0x2a {
+		t.Fatalf("expected field value 0x2a, got %#x", got)
+	}
+
+	wantOffset := region.offset*8 + field.bitOffset
+	if _, ok := handler.mem[wantOffset]; !ok {
+		t.Fatalf("expected the handler to be hit at bit offset %d", wantOffset)
+	}
+}
+
+func TestFieldUnitReadPastRegionLengthReturnsError(t *testing.T) {
+	vm := NewVM(nil, nil)
+	handler := newFakeRegionHandler()
+	vm.RegisterRegionHandler(RegionSpaceEmbeddedControl, handler)
+
+	region := &regionEntity{namedEntity: namedEntity{name: "EC0_"}, space: RegionSpaceEmbeddedControl, length: 1}
+	field := &fieldUnitEntity{namedEntity: namedEntity{name: "OVER"}, region: region, bitOffset: 4, bitWidth: 8}
+
+	ctx := &execContext{vm: vm}
+	if _, err := field.Read(ctx); err != errFieldOutOfBounds {
+		t.Fatalf("expected errFieldOutOfBounds, got %v", err)
+	}
+	if err := field.Write(ctx, 1); err != errFieldOutOfBounds {
+		t.Fatalf("expected errFieldOutOfBounds, got %v", err)
+	}
+}
+
+func TestFieldUnitReadWithoutRegionReturnsError(t *testing.T) {
+	field := &fieldUnitEntity{namedEntity: namedEntity{name: "ORPHAN"}}
+	ctx := &execContext{vm: NewVM(nil, nil)}
+
+	if _, err := field.Read(ctx); err != errFieldParentNotRegion {
+		t.Fatalf("expected errFieldParentNotRegion, got %v", err)
+	}
+}
+
+func TestIndexFieldRoutesThroughIndexAndDataRegisters(t *testing.T) {
+	vm := NewVM(nil, nil)
+	handler := newFakeRegionHandler()
+	vm.RegisterRegionHandler(RegionSpaceSystemIO, handler)
+
+	region := &regionEntity{namedEntity: namedEntity{name: "PNP0"}, space: RegionSpaceSystemIO, length: 2}
+	indexReg := &fieldUnitEntity{namedEntity: namedEntity{name: "IDX0"}, region: region, bitOffset: 0, bitWidth: 8}
+	dataReg := &fieldUnitEntity{namedEntity: namedEntity{name: "DAT0"}, region: region, bitOffset: 8, bitWidth: 8}
+
+	field := &indexFieldEntity{namedEntity: namedEntity{name: "FLD0"}, byteOffset: 5, bitWidth: 8}
+	field.indexReg = indexReg
+	field.dataReg = dataReg
+
+	ctx := &execContext{vm: vm}
+	if err := field.Write(ctx, 0x55); err != nil {
+		t.Fatalf("unexpected error writing index field: %s", err)
+	}
+
+	if got := handler.mem[region.offset*8+indexReg.bitOffset]; got != 5 {
+		t.Fatalf("expected index register to be latched to 5, got %d", got)
+	}
+
+	got, err := field.Read(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error reading index field: %s", err)
+	}
+	if got != 0x55 {
+		t.Fatalf("expected 0x55, got %#x", got)
+	}
+}
+
+func TestBankFieldLatchesBankSelectorBeforeAccess(t *testing.T) {
+	vm := NewVM(nil, nil)
+	handler := newFakeRegionHandler()
+	vm.RegisterRegionHandler(RegionSpaceSystemIO, handler)
+
+	region := &regionEntity{namedEntity: namedEntity{name: "BNK0"}, space: RegionSpaceSystemIO, length: 2}
+	bankSelector := &fieldUnitEntity{namedEntity: namedEntity{name: "BSEL"}, region: region, bitOffset: 0, bitWidth: 8}
+
+	field := &bankFieldEntity{
+		fieldUnitEntity: fieldUnitEntity{
+			namedEntity: namedEntity{name: "FLD1"},
+			region:      region,
+			bitOffset:   8,
+			bitWidth:    8,
+		},
+		bankSelector: bankSelector,
+		bankValue:    1,
+	}
+
+	ctx := &execContext{vm: vm}
+	if err := field.Write(ctx, 0x77); err != nil {
+		t.Fatalf("unexpected error writing bank field: %s", err)
+	}
+
+	if got := handler.mem[region.offset*8+bankSelector.bitOffset]; got != 1 {
+		t.Fatalf("expected bank selector to be latched to 1, got %d", got)
+	}
+
+	got, err := field.Read(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error reading bank field: %s", err)
+	}
+	if got != 0x77 {
+		t.Fatalf("expected 0x77, got %#x", got)
+	}
+}
+
+// TestDefaultSystemMemoryHandlerRoutesFieldAccess exercises a field unit
+// backed by the VM's default SystemMemory RegionHandler, the one installed
+// automatically by NewVM. It requires the arch package's physical memory
+// accessors to be importable.
+func TestDefaultSystemMemoryHandlerRoutesFieldAccess(t *testing.T) {
+	vm := NewVM(nil, nil)
+
+	region := &regionEntity{
+		namedEntity: namedEntity{name: "SYSM"},
+		space:       RegionSpaceSystemMemory,
+		offset:      0,
+		length:      8,
+	}
+	field := &fieldUnitEntity{
+		namedEntity: namedEntity{name: "FLD0"},
+		region:      region,
+		bitOffset:   0,
+		bitWidth:    32,
+	}
+
+	ctx := &execContext{vm: vm}
+	if err := field.Write(ctx, 0xdeadbeef); err != nil {
+		t.Fatalf("unexpected error writing field: %s", err)
+	}
+
+	got, err := field.Read(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error reading field: %s", err)
+	}
+	if got != 0xdeadbeef {
+		t.Fatalf("expected 0xdeadbeef, got %#x", got)
+	}
+}
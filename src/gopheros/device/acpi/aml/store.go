@@ -0,0 +1,56 @@
+package aml
+
+// vmStore implements the Store(src, dst) operator: it resolves dst and
+// copies the (possibly converted) value of src into it. Destinations are
+// special-cased in this order: a reference (objRef) to a local/method
+// argument or named object, the reserved Debug object, a field unit backed
+// by an OperationRegion, and finally a plain named object.
+func (vm *VM) vmStore(ctx *execContext, dst, src interface{}) *Error {
+	if dst == nil || src == nil {
+		return errNilStoreOperands
+	}
+
+	if ref, ok := dst.(*objRef); ok {
+		return vm.vmStoreToRef(ctx, ref, src)
+	}
+
+	switch target := dst.(type) {
+	case *debugEntity:
+		vm.vmStoreDebug(ctx, src)
+		return nil
+	case *fieldUnitEntity:
+		val, err := vmConvert(ctx, src, valueTypeInteger)
+		if err != nil {
+			return err
+		}
+		return target.Write(ctx, val.(uint64))
+	case *namedEntity:
+		target.args = append(target.args[:0], src)
+		return nil
+	default:
+		return errInvalidStoreDestination
+	}
+}
+
+// vmStoreToRef implements a store into an objRef, which is either a
+// reference to a Local/Method argument slot or to a named AML object;
+// different rules apply to each (p.884 of the spec).
+func (vm *VM) vmStoreToRef(ctx *execContext, ref *objRef, src interface{}) *Error {
+	if ref.isArgRef {
+		ref.ref = src
+		return nil
+	}
+
+	return vm.vmStore(ctx, ref.ref, src)
+}
+
+// vmOpStore implements the Store() opcode: args[0] is the source operand,
+// args[1] the destination.
+func vmOpStore(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 2 {
+		return errInvalidStoreDestination
+	}
+
+	return ctx.vm.vmStore(ctx, namedEnt.args[1], namedEnt.args[0])
+}
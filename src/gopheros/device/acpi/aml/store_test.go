@@ -0,0 +1,55 @@
+package aml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestVMStoreToDebugWritesFormattedValue(t *testing.T) {
+	vm := NewVM(nil, nil)
+	var buf bytes.Buffer
+	vm.SetDebugWriter(&buf)
+
+	debugObj := &debugEntity{namedEntity: namedEntity{name: "Debug"}}
+
+	ctx := &execContext{vm: vm}
+	if err := vm.vmStore(ctx, debugObj, "hello"); err != nil {
+		t.Fatalf("unexpected error storing to Debug: %s", err)
+	}
+
+	if !strings.Contains(buf.String(), "hello") {
+		t.Fatalf("expected debug writer to contain the stored string, got %q", buf.String())
+	}
+}
+
+func TestVMStoreToFieldUnitRoutesThroughRegionHandler(t *testing.T) {
+	vm := NewVM(nil, nil)
+	handler := newFakeRegionHandler()
+	vm.RegisterRegionHandler(RegionSpaceSystemCMOS, handler)
+
+	region := &regionEntity{namedEntity: namedEntity{name: "CMOS"}, space: RegionSpaceSystemCMOS, length: 1}
+	field := &fieldUnitEntity{namedEntity: namedEntity{name: "SEC0"}, region: region, bitWidth: 8}
+
+	ctx := &execContext{vm: vm}
+	if err := vm.vmStore(ctx, field, uint64(0x37)); err != nil {
+		t.Fatalf("unexpected error storing to field: %s", err)
+	}
+
+	got, err := field.Read(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error reading field back: %s", err)
+	}
+	if got != 0x37 {
+		t.Fatalf("expected 0x37, got %#x", got)
+	}
+}
+
+func TestVMStoreNilOperandsReturnsError(t *testing.T) {
+	vm := NewVM(nil, nil)
+	ctx := &execContext{vm: vm}
+
+	if err := vm.vmStore(ctx, nil, uint64(1)); err != errNilStoreOperands {
+		t.Fatalf("expected errNilStoreOperands, got %v", err)
+	}
+}
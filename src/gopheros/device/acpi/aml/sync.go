@@ -0,0 +1,463 @@
+package aml
+
+import (
+	"sync"
+	"time"
+)
+
+// infiniteTimeout is the AML sentinel value for the Acquire/Wait timeout
+// operand that indicates the caller is willing to block indefinitely.
+const infiniteTimeout = 0xffff
+
+var (
+	errSyncLevelViolation = &Error{message: "vm: cannot acquire a mutex at or below the sync level currently held"}
+	errMutexNotOwned      = &Error{message: "vm: Release called on a mutex that the caller does not own"}
+	errGlobalLockNotWired = &Error{message: "vm: the \\_GL mutex has no GlobalLock implementation registered"}
+	errNotAMutex          = &Error{message: "vm: Acquire/Release operand does not resolve to a Mutex"}
+	errNotAnEvent         = &Error{message: "vm: Signal/Wait/Reset operand does not resolve to an Event"}
+)
+
+// globalLockInfiniteTimeout is passed to GlobalLock.Acquire to request that
+// it block indefinitely, i.e. with no deadline. It is deliberately a huge
+// (but finite) duration rather than a negative one: negative durations are
+// already-expired deadlines under the usual time.After/context.WithTimeout
+// idioms, which is the opposite of what "block forever" means.
+const globalLockInfiniteTimeout = time.Duration(1<<63 - 1)
+
+// GlobalLock is implemented by the host kernel to arbitrate the ACPI
+// Global Lock, a firmware/OS shared semaphore exposed via the FACS. The
+// reserved \_GL mutex delegates to this interface instead of using a plain
+// in-process mutex.
+type GlobalLock interface {
+	// Acquire attempts to take the global lock, blocking for up to
+	// timeout. A timeout of 0 means "try once and return immediately";
+	// globalLockInfiniteTimeout means "block forever". Implementations
+	// must treat timeout as a duration to wait, not a deadline, so they
+	// must not special-case it beyond that.
+	Acquire(timeout time.Duration) bool
+
+	// Release gives up ownership of the global lock previously obtained
+	// via Acquire.
+	Release()
+}
+
+// mutexEntity is the runtime representation of a DefMutex declaration.
+type mutexEntity struct {
+	namedEntity
+
+	// syncLevel is the SyncLevel operand from the Mutex() declaration; it
+	// bounds the set of mutexes that may be acquired while this one is
+	// held (p.262 of the spec).
+	syncLevel uint8
+
+	mu sync.Mutex
+
+	// owner identifies the execContext that currently holds the mutex, or
+	// nil if it is free.
+	owner *execContext
+
+	// depth tracks recursive acquisitions by the current owner.
+	depth uint32
+}
+
+// eventEntity is the runtime representation of a DefEvent declaration. It
+// wraps a counting semaphore: Signal() increments it, Wait() blocks until
+// it is non-zero then decrements it, and Reset() clears any pending
+// signals.
+type eventEntity struct {
+	namedEntity
+
+	mu    sync.Mutex
+	count uint64
+}
+
+// newEventEntity allocates an eventEntity.
+func newEventEntity(name string) *eventEntity {
+	return &eventEntity{namedEntity: namedEntity{name: name}}
+}
+
+// SetGlobalLock installs the host-provided implementation that backs the
+// reserved \_GL mutex. It must be called before any AML that touches \_GL
+// (typically during platform init) or Acquire/Release against it will fail
+// with errGlobalLockNotWired.
+func (vm *VM) SetGlobalLock(gl GlobalLock) {
+	vm.globalLock = gl
+}
+
+// vmAcquireMutex implements the Acquire() opcode for m on behalf of ctx,
+// honoring SyncLevel ordering, recursive acquisition and the timeout
+// operand (infiniteTimeout meaning "block forever"). It returns the
+// AML-level boolean acquire result (true on timeout/failure, matching the
+// ACPI spec's "not acquired" convention).
+func (vm *VM) vmAcquireMutex(ctx *execContext, m *mutexEntity, timeoutMs uint16) (bool, *Error) {
+	if m == vm.globalLockMutex {
+		if vm.globalLock == nil {
+			return true, errGlobalLockNotWired
+		}
+
+		timeout := time.Duration(timeoutMs) * time.Millisecond
+		if timeoutMs == infiniteTimeout {
+			timeout = globalLockInfiniteTimeout
+		}
+
+		return !vm.globalLock.Acquire(timeout), nil
+	}
+
+	m.mu.Lock()
+	alreadyOwned := m.owner == ctx
+	m.mu.Unlock()
+
+	// SyncLevel ordering (p.262) only constrains acquiring a *new* mutex;
+	// recursively reacquiring one already held by ctx is always allowed.
+	// This is checked once, up front, so that a caller violating the
+	// ordering fails fast with errSyncLevelViolation instead of sitting in
+	// the wait loop below until its timeout (or forever, for the
+	// infiniteTimeout acquires vmEnterMethod issues on every Serialized
+	// method entry) expires for no reason.
+	if !alreadyOwned {
+		if maxHeld, held := ctx.maxHeldSyncLevel(); held && m.syncLevel <= maxHeld {
+			return true, errSyncLevelViolation
+		}
+	}
+
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	for {
+		m.mu.Lock()
+		if m.owner == nil || m.owner == ctx {
+			if m.owner == ctx {
+				m.depth++
+				m.mu.Unlock()
+				return false, nil
+			}
+
+			m.owner = ctx
+			m.depth = 1
+			m.mu.Unlock()
+			ctx.pushSyncLevel(m.syncLevel)
+			return false, nil
+		}
+		m.mu.Unlock()
+
+		if timeoutMs != infiniteTimeout && time.Now().After(deadline) {
+			return true, nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// vmReleaseMutex implements the Release() opcode for m on behalf of ctx.
+func (vm *VM) vmReleaseMutex(ctx *execContext, m *mutexEntity) *Error {
+	if m == vm.globalLockMutex {
+		if vm.globalLock == nil {
+			return errGlobalLockNotWired
+		}
+		vm.globalLock.Release()
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.owner != ctx {
+		return errMutexNotOwned
+	}
+
+	m.depth--
+	if m.depth == 0 {
+		m.owner = nil
+		ctx.popSyncLevel(m.syncLevel)
+	}
+
+	return nil
+}
+
+// vmSignalEvent implements the Signal() opcode.
+func (vm *VM) vmSignalEvent(ev *eventEntity) {
+	ev.mu.Lock()
+	ev.count++
+	ev.mu.Unlock()
+}
+
+// vmResetEvent implements the Reset() opcode, clearing any pending signals.
+func (vm *VM) vmResetEvent(ev *eventEntity) {
+	ev.mu.Lock()
+	ev.count = 0
+	ev.mu.Unlock()
+}
+
+// vmWaitEvent implements the Wait() opcode, blocking until ev is signaled
+// or timeoutMs elapses. It returns the AML-level boolean result (true on
+// timeout). Polling (rather than a condition variable) is used so that the
+// timeout operand can be honored even if ev is never signaled.
+func (vm *VM) vmWaitEvent(ev *eventEntity, timeoutMs uint16) bool {
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+
+	for {
+		ev.mu.Lock()
+		if ev.count > 0 {
+			ev.count--
+			ev.mu.Unlock()
+			return false
+		}
+		ev.mu.Unlock()
+
+		if timeoutMs != infiniteTimeout && time.Now().After(deadline) {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// implicitMethodMutex returns the mutex that backs a Serialized method's
+// implicit locking. Each *Method gets its own lazily-created mutexEntity
+// the first time it is entered; subsequent calls reuse it.
+func implicitMethodMutex(m *Method) *mutexEntity {
+	if m.implicitMutex == nil {
+		m.implicitMutex = &mutexEntity{
+			namedEntity: namedEntity{name: m.name + ".$mutex"},
+			syncLevel:   m.syncLevel,
+		}
+	}
+
+	return m.implicitMutex
+}
+
+// vmEnterMethod is invoked by the method call path before running m's body.
+// If m was declared with the Serialized bit set, it transparently acquires
+// m's implicit mutex at m.syncLevel, mirroring the spec's treatment of
+// Serialized methods as sugar for "wrap the body in Acquire/Release".
+func (vm *VM) vmEnterMethod(ctx *execContext, m *Method) *Error {
+	if !m.serialized {
+		return nil
+	}
+
+	if timedOut, err := vm.vmAcquireMutex(ctx, implicitMethodMutex(m), infiniteTimeout); err != nil {
+		return err
+	} else if timedOut {
+		return errSyncLevelViolation
+	}
+
+	return nil
+}
+
+// vmExitMethod releases the implicit mutex acquired by vmEnterMethod, if
+// any.
+func (vm *VM) vmExitMethod(ctx *execContext, m *Method) *Error {
+	if !m.serialized {
+		return nil
+	}
+
+	return vm.vmReleaseMutex(ctx, implicitMethodMutex(m))
+}
+
+// pushSyncLevel records that ctx now additionally holds a mutex at level.
+// Levels are tracked as a multiset (not a single scalar) because a method
+// can hold more than one mutex concurrently, and releasing the most
+// recently acquired one must not forget about the levels still held by the
+// others.
+func (ctx *execContext) pushSyncLevel(level uint8) {
+	ctx.heldSyncLevels = append(ctx.heldSyncLevels, level)
+}
+
+// popSyncLevel removes a single occurrence of level from the set of sync
+// levels ctx holds, once the owning mutex at that level has been fully
+// released.
+func (ctx *execContext) popSyncLevel(level uint8) {
+	for i, held := range ctx.heldSyncLevels {
+		if held == level {
+			ctx.heldSyncLevels = append(ctx.heldSyncLevels[:i], ctx.heldSyncLevels[i+1:]...)
+			return
+		}
+	}
+}
+
+// unwrapRef unwraps an objRef indirection, if any, and peels away a generic
+// namedEntity wrapper around a single nested Entity (the same shape
+// checkEntities unwraps for DefBuffer/DefPackage), returning the value it
+// ultimately points to.
+func unwrapRef(v interface{}) interface{} {
+	if ref, ok := v.(*objRef); ok {
+		v = ref.ref
+	}
+	if namedEnt, ok := v.(*namedEntity); ok && len(namedEnt.args) == 1 {
+		if nested, ok := namedEnt.args[0].(Entity); ok {
+			return nested
+		}
+	}
+	return v
+}
+
+// mutexOperand extracts the target *mutexEntity from v, unwrapping an
+// objRef indirection if present.
+func mutexOperand(v interface{}) (*mutexEntity, bool) {
+	m, ok := unwrapRef(v).(*mutexEntity)
+	return m, ok
+}
+
+// eventOperand extracts the target *eventEntity from v, unwrapping an
+// objRef indirection if present.
+func eventOperand(v interface{}) (*eventEntity, bool) {
+	ev, ok := unwrapRef(v).(*eventEntity)
+	return ev, ok
+}
+
+// vmOpAcquire implements the Acquire() opcode: args[0] is the target
+// Mutex, args[1] the timeout in milliseconds. The AML-level boolean
+// acquire result is stored in ctx.retVal.
+func vmOpAcquire(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 2 {
+		return errNotAMutex
+	}
+
+	m, ok := mutexOperand(namedEnt.args[0])
+	if !ok {
+		return errNotAMutex
+	}
+
+	timeout, ok := namedEnt.args[1].(uint64)
+	if !ok {
+		return errNotAMutex
+	}
+
+	timedOut, err := ctx.vm.vmAcquireMutex(ctx, m, uint16(timeout))
+	if err != nil {
+		return err
+	}
+
+	ctx.retVal = timedOut
+	return nil
+}
+
+// vmOpRelease implements the Release() opcode: args[0] is the target
+// Mutex.
+func vmOpRelease(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 1 {
+		return errNotAMutex
+	}
+
+	m, ok := mutexOperand(namedEnt.args[0])
+	if !ok {
+		return errNotAMutex
+	}
+
+	return ctx.vm.vmReleaseMutex(ctx, m)
+}
+
+// vmOpSignal implements the Signal() opcode: args[0] is the target Event.
+func vmOpSignal(ctx *execContext, ent Entity) *Error {
+	ev, err := resolveEventOperand(ent)
+	if err != nil {
+		return err
+	}
+
+	ctx.vm.vmSignalEvent(ev)
+	return nil
+}
+
+// vmOpReset implements the Reset() opcode: args[0] is the target Event.
+func vmOpReset(ctx *execContext, ent Entity) *Error {
+	ev, err := resolveEventOperand(ent)
+	if err != nil {
+		return err
+	}
+
+	ctx.vm.vmResetEvent(ev)
+	return nil
+}
+
+// vmOpWait implements the Wait() opcode: args[0] is the target Event,
+// args[1] the timeout in milliseconds. The AML-level boolean timeout
+// result is stored in ctx.retVal.
+func vmOpWait(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 2 {
+		return errNotAnEvent
+	}
+
+	ev, ok := eventOperand(namedEnt.args[0])
+	if !ok {
+		return errNotAnEvent
+	}
+
+	timeout, ok := namedEnt.args[1].(uint64)
+	if !ok {
+		return errNotAnEvent
+	}
+
+	ctx.retVal = ctx.vm.vmWaitEvent(ev, uint16(timeout))
+	return nil
+}
+
+// resolveEventOperand extracts the target *eventEntity from args[0] of a
+// Signal()/Reset() opcode entity.
+func resolveEventOperand(ent Entity) (*eventEntity, *Error) {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 1 {
+		return nil, errNotAnEvent
+	}
+
+	ev, ok := eventOperand(namedEnt.args[0])
+	if !ok {
+		return nil, errNotAnEvent
+	}
+
+	return ev, nil
+}
+
+// vmOpMutex implements the Mutex() declaration opcode: it builds a
+// *mutexEntity from the declaration's SyncLevel operand and stashes it as
+// the sole argument of the placeholder namedEntity the parser created for
+// the name, so that later lookups of the name unwrap (via unwrapRef) to the
+// mutexEntity directly.
+func vmOpMutex(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok || len(namedEnt.args) < 1 {
+		return errNotAMutex
+	}
+
+	syncLevel, err := vmConvert(ctx, namedEnt.args[0], valueTypeInteger)
+	if err != nil {
+		return err
+	}
+
+	namedEnt.args = []interface{}{&mutexEntity{
+		namedEntity: namedEntity{name: namedEnt.name},
+		syncLevel:   uint8(syncLevel.(uint64)),
+	}}
+	return nil
+}
+
+// vmOpEvent implements the Event() declaration opcode: it builds a fresh
+// *eventEntity and stashes it as the sole argument of the placeholder
+// namedEntity the parser created for the name, so that later lookups of the
+// name unwrap (via unwrapRef) to the eventEntity directly. Event() takes no
+// operands beyond the name itself.
+func vmOpEvent(ctx *execContext, ent Entity) *Error {
+	namedEnt, ok := ent.(*namedEntity)
+	if !ok {
+		return errNotAnEvent
+	}
+
+	namedEnt.args = []interface{}{newEventEntity(namedEnt.name)}
+	return nil
+}
+
+// maxHeldSyncLevel returns the highest SyncLevel among the mutexes ctx
+// currently holds, and whether it holds any at all. A new mutex may only
+// be acquired at a level strictly greater than this value.
+func (ctx *execContext) maxHeldSyncLevel() (uint8, bool) {
+	if len(ctx.heldSyncLevels) == 0 {
+		return 0, false
+	}
+
+	max := ctx.heldSyncLevels[0]
+	for _, level := range ctx.heldSyncLevels[1:] {
+		if level > max {
+			max = level
+		}
+	}
+
+	return max, true
+}
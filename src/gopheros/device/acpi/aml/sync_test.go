@@ -0,0 +1,146 @@
+package aml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSyncLevelOrderingAcrossMultipleHeldMutexes(t *testing.T) {
+	vm := NewVM(nil, nil)
+	ctx := &execContext{vm: vm}
+
+	mutexA := &mutexEntity{namedEntity: namedEntity{name: "MTXA"}, syncLevel: 2}
+	mutexB := &mutexEntity{namedEntity: namedEntity{name: "MTXB"}, syncLevel: 4}
+	mutexC := &mutexEntity{namedEntity: namedEntity{name: "MTXC"}, syncLevel: 1}
+
+	if timedOut, err := vm.vmAcquireMutex(ctx, mutexA, infiniteTimeout); err != nil || timedOut {
+		t.Fatalf("failed to acquire mutexA: timedOut=%v err=%v", timedOut, err)
+	}
+	if timedOut, err := vm.vmAcquireMutex(ctx, mutexB, infiniteTimeout); err != nil || timedOut {
+		t.Fatalf("failed to acquire mutexB: timedOut=%v err=%v", timedOut, err)
+	}
+
+	if err := vm.vmReleaseMutex(ctx, mutexB); err != nil {
+		t.Fatalf("failed to release mutexB: %v", err)
+	}
+
+	// mutexA (level 2) is still held, so acquiring mutexC (level 1) must be
+	// rejected even though mutexB (the most recently released mutex) was
+	// at a higher level.
+	if timedOut, err := vm.vmAcquireMutex(ctx, mutexC, infiniteTimeout); err != errSyncLevelViolation {
+		t.Fatalf("expected errSyncLevelViolation while mutexA is held, got timedOut=%v err=%v", timedOut, err)
+	}
+
+	if err := vm.vmReleaseMutex(ctx, mutexA); err != nil {
+		t.Fatalf("failed to release mutexA: %v", err)
+	}
+
+	if timedOut, err := vm.vmAcquireMutex(ctx, mutexC, infiniteTimeout); err != nil || timedOut {
+		t.Fatalf("expected mutexC to be acquirable once mutexA is released: timedOut=%v err=%v", timedOut, err)
+	}
+}
+
+func TestSyncLevelViolationFailsFastEvenWhenMutexIsContended(t *testing.T) {
+	vm := NewVM(nil, nil)
+	owner := &execContext{vm: vm}
+	caller := &execContext{vm: vm}
+
+	heldByCaller := &mutexEntity{namedEntity: namedEntity{name: "MTXH"}, syncLevel: 3}
+	contended := &mutexEntity{namedEntity: namedEntity{name: "MTXC"}, syncLevel: 1}
+
+	// owner holds contended forever, so caller would block in the wait loop
+	// indefinitely if the SyncLevel check did not run before it.
+	if timedOut, err := vm.vmAcquireMutex(owner, contended, infiniteTimeout); err != nil || timedOut {
+		t.Fatalf("failed to acquire contended: timedOut=%v err=%v", timedOut, err)
+	}
+	if timedOut, err := vm.vmAcquireMutex(caller, heldByCaller, infiniteTimeout); err != nil || timedOut {
+		t.Fatalf("failed to acquire heldByCaller: timedOut=%v err=%v", timedOut, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if timedOut, err := vm.vmAcquireMutex(caller, contended, infiniteTimeout); err != errSyncLevelViolation {
+			t.Errorf("expected errSyncLevelViolation, got timedOut=%v err=%v", timedOut, err)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("vmAcquireMutex did not fail fast on a SyncLevel violation against a contended mutex")
+	}
+}
+
+func TestMutexRecursiveAcquisition(t *testing.T) {
+	vm := NewVM(nil, nil)
+	ctx := &execContext{vm: vm}
+	m := &mutexEntity{namedEntity: namedEntity{name: "MTX0"}, syncLevel: 0}
+
+	for i := 0; i < 3; i++ {
+		if timedOut, err := vm.vmAcquireMutex(ctx, m, infiniteTimeout); err != nil || timedOut {
+			t.Fatalf("recursive acquire %d failed: timedOut=%v err=%v", i, timedOut, err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := vm.vmReleaseMutex(ctx, m); err != nil {
+			t.Fatalf("release %d failed: %v", i, err)
+		}
+		if m.owner == nil {
+			t.Fatalf("mutex released too early after %d of 3 releases", i+1)
+		}
+	}
+
+	if err := vm.vmReleaseMutex(ctx, m); err != nil {
+		t.Fatalf("final release failed: %v", err)
+	}
+	if m.owner != nil {
+		t.Fatal("expected mutex to be free after matching every acquire with a release")
+	}
+}
+
+func TestVMOpMutexBuildsMutexEntityFromSyncLevelOperand(t *testing.T) {
+	ctx := &execContext{vm: NewVM(nil, nil)}
+	decl := &namedEntity{name: "MTX0", args: []interface{}{uint64(3)}}
+
+	if err := vmOpMutex(ctx, decl); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	m, ok := mutexOperand(decl)
+	if !ok {
+		t.Fatalf("expected decl to unwrap to a *mutexEntity, got %#v", decl.args[0])
+	}
+	if m.syncLevel != 3 {
+		t.Fatalf("expected syncLevel 3, got %d", m.syncLevel)
+	}
+}
+
+func TestVMOpEventBuildsEventEntity(t *testing.T) {
+	ctx := &execContext{vm: NewVM(nil, nil)}
+	decl := &namedEntity{name: "EVT0"}
+
+	if err := vmOpEvent(ctx, decl); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := eventOperand(decl); !ok {
+		t.Fatalf("expected decl to unwrap to a *eventEntity, got %#v", decl.args[0])
+	}
+}
+
+func TestEventSignalWait(t *testing.T) {
+	vm := NewVM(nil, nil)
+	ev := newEventEntity("EVT0")
+
+	vm.vmSignalEvent(ev)
+
+	if timedOut := vm.vmWaitEvent(ev, 0); timedOut {
+		t.Fatal("expected Wait() to succeed immediately after Signal()")
+	}
+
+	if timedOut := vm.vmWaitEvent(ev, 1); !timedOut {
+		t.Fatal("expected Wait() to time out on an unsignaled event")
+	}
+}
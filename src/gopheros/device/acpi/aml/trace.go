@@ -0,0 +1,149 @@
+package aml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Tracer is notified of interpreter activity as execBlock dispatches each
+// opcode and as methods are entered/exited. Implementations must be safe to
+// call from the interpreter's goroutine; they are invoked synchronously and
+// can therefore block execution (e.g. a breakpoint tracer waiting for a
+// developer to resume).
+type Tracer interface {
+	// BeforeOp is called immediately before ent's opcode is dispatched.
+	BeforeOp(ctx *execContext, ent Entity)
+
+	// AfterOp is called immediately after ent's opcode has been
+	// dispatched, with the error (if any) it returned.
+	AfterOp(ctx *execContext, ent Entity, err *Error)
+
+	// OnMethodEnter is called before a method's body begins executing.
+	OnMethodEnter(ctx *execContext, m *Method)
+
+	// OnMethodExit is called after a method's body has finished
+	// executing, with the error (if any) it returned.
+	OnMethodExit(ctx *execContext, m *Method, err *Error)
+
+	// OnRegionAccess is called whenever a field unit access is about to
+	// be routed to a RegionHandler.
+	OnRegionAccess(ctx *execContext, space RegionSpace, offset, bitWidth uint64, isWrite bool)
+}
+
+// SetTracer installs t as the active Tracer, or clears it if t is nil. When
+// set, execBlock calls into t around every opcode dispatch and
+// InvokeMethod calls into t around the method it runs; otherwise both run
+// with no observability.
+func (vm *VM) SetTracer(t Tracer) {
+	vm.tracer = t
+}
+
+// absPath reconstructs the absolute AML path of ent by walking its parent
+// chain, for use in trace output and breakpoint matching.
+func absPath(ent Entity) string {
+	type named interface {
+		Name() string
+	}
+
+	var segments []string
+	for cur := ent; cur != nil; cur = entityParent(cur) {
+		if n, ok := cur.(named); ok && n.Name() != `\` {
+			segments = append(segments, n.Name())
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('\\')
+	for i := len(segments) - 1; i >= 0; i-- {
+		if i != len(segments)-1 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(segments[i])
+	}
+	return sb.String()
+}
+
+// textTracer is a built-in Tracer that prints a human-readable trace of
+// opcode dispatch, method entry/exit and region accesses to an io.Writer.
+// It is intended for interactively debugging a misbehaving DSDT/SSDT.
+type textTracer struct {
+	w io.Writer
+}
+
+// NewTextTracer creates a Tracer that writes a line per traced event to w.
+func NewTextTracer(w io.Writer) Tracer {
+	return &textTracer{w: w}
+}
+
+// BeforeOp implements Tracer.
+func (t *textTracer) BeforeOp(ctx *execContext, ent Entity) {
+	fmt.Fprintf(t.w, "op %#02x at %s localArg=%v methodArg=%v\n",
+		ent.getOpcode(), absPath(ent), ctx.localArg, ctx.methodArg)
+}
+
+// AfterOp implements Tracer.
+func (t *textTracer) AfterOp(ctx *execContext, ent Entity, err *Error) {
+	if err != nil {
+		fmt.Fprintf(t.w, "op %#02x at %s failed: %s\n", ent.getOpcode(), absPath(ent), err)
+	}
+}
+
+// OnMethodEnter implements Tracer.
+func (t *textTracer) OnMethodEnter(ctx *execContext, m *Method) {
+	fmt.Fprintf(t.w, "--> enter %s\n", absPath(m))
+}
+
+// OnMethodExit implements Tracer.
+func (t *textTracer) OnMethodExit(ctx *execContext, m *Method, err *Error) {
+	if err != nil {
+		fmt.Fprintf(t.w, "<-- exit %s (error: %s)\n", absPath(m), err)
+		return
+	}
+	fmt.Fprintf(t.w, "<-- exit %s (retVal: %v)\n", absPath(m), ctx.retVal)
+}
+
+// OnRegionAccess implements Tracer.
+func (t *textTracer) OnRegionAccess(ctx *execContext, space RegionSpace, offset, bitWidth uint64, isWrite bool) {
+	dir := "read"
+	if isWrite {
+		dir = "write"
+	}
+	fmt.Fprintf(t.w, "region %s space=%d offset=%d bitWidth=%d\n", dir, space, offset, bitWidth)
+}
+
+// breakpointTracer pauses execution by blocking until resume() returns
+// whenever a method whose absolute path is in the breakpoint set is
+// entered, letting a developer attach and inspect VM state before
+// continuing.
+type breakpointTracer struct {
+	textTracer
+	breakpoints map[string]bool
+	resume      func(absPath string)
+}
+
+// NewBreakpointTracer creates a Tracer that behaves like the built-in text
+// tracer but additionally invokes onBreak and blocks until it returns
+// whenever execution enters a method whose absolute path is in paths.
+func NewBreakpointTracer(w io.Writer, paths []string, onBreak func(absPath string)) Tracer {
+	set := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		set[p] = true
+	}
+
+	return &breakpointTracer{
+		textTracer:  textTracer{w: w},
+		breakpoints: set,
+		resume:      onBreak,
+	}
+}
+
+// OnMethodEnter implements Tracer.
+func (t *breakpointTracer) OnMethodEnter(ctx *execContext, m *Method) {
+	t.textTracer.OnMethodEnter(ctx, m)
+
+	path := absPath(m)
+	if t.breakpoints[path] && t.resume != nil {
+		t.resume(path)
+	}
+}
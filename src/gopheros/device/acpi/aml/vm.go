@@ -23,6 +23,10 @@ var (
 	errWhileBodyNotScopedEntity  = &Error{message: "vmOpWHile: Wihile body must be a scoped entity"}
 	errIfBodyNotScopedEntity     = &Error{message: "vmOpIf: If body must be a scoped entity"}
 	errElseBodyNotScopedEntity   = &Error{message: "vmOpIf: Else body must be a scoped entity"}
+	errUnhandledRegionSpace      = &Error{message: "vm: no handler registered for the requested OperationRegion address space"}
+	errFieldParentNotRegion      = &Error{message: "vm: field unit does not reference an OperationRegion"}
+	errFieldOutOfBounds          = &Error{message: "vm: field unit access exceeds the length of its OperationRegion"}
+	errLookupFailed              = &Error{message: "vm: failed to locate the requested entity"}
 )
 
 // objRef is a pointer to an argument (local or global) or a named AML object.
@@ -60,6 +64,13 @@ type execContext struct {
 	// opcode execution.
 	retVal interface{}
 
+	// heldSyncLevels tracks the SyncLevel of every mutex this execution
+	// currently holds (a method can hold more than one at a time), used
+	// to enforce the spec's SyncLevel ordering rule: a new mutex may only
+	// be acquired at a level strictly greater than the highest level
+	// already held.
+	heldSyncLevels []uint8
+
 	vm *VM
 }
 
@@ -89,20 +100,77 @@ type VM struct {
 	// value so that it can be used by the data conversion helpers.
 	sizeOfIntInBits int
 
+	// regionHandlers maps an ACPI OperationRegion address space to the
+	// handler responsible for servicing reads/writes against it.
+	regionHandlers map[RegionSpace]RegionHandler
+
+	// notifyHandlers holds the handlers registered via
+	// RegisterNotifyHandler, in registration order.
+	notifyHandlers []notifyBinding
+
+	// globalLock backs the reserved \_GL mutex; it is nil until the host
+	// wires one up via SetGlobalLock.
+	globalLock GlobalLock
+
+	// globalLockMutex is the \_GL mutexEntity singleton; Acquire/Release
+	// against it are special-cased to delegate to globalLock.
+	globalLockMutex *mutexEntity
+
+	// debugWriter is the sink that stores to the AML Debug object are
+	// written to. It defaults to errWriter.
+	debugWriter io.Writer
+
+	// debugLevel filters which stores to the Debug object are forwarded
+	// to debugWriter.
+	debugLevel DebugLevel
+
+	// tracer, when non-nil, is notified around opcode dispatch, method
+	// calls and region accesses. It is nil by default so tracing has no
+	// overhead unless a caller opts in via SetTracer.
+	tracer Tracer
+
 	jumpTable [numOpcodes]opHandler
 }
 
+// entityWithParent is implemented by entities that track their enclosing
+// scope, allowing helpers such as notifyHandlerFor to walk up the namespace
+// tree.
+type entityWithParent interface {
+	Parent() Entity
+}
+
+// entityParent returns the enclosing scope of ent, or nil if ent is the
+// root of the namespace or does not track its parent.
+func entityParent(ent Entity) Entity {
+	if p, ok := ent.(entityWithParent); ok {
+		return p.Parent()
+	}
+
+	return nil
+}
+
 // NewVM creates a new AML VM and initializes it with the default scope
 // hierarchy and pre-defined objects contained in the ACPI specification.
 func NewVM(errWriter io.Writer, resolver table.Resolver) *VM {
 	root := defaultACPIScopes()
 
-	return &VM{
-		rootNS:        root,
-		errWriter:     errWriter,
-		tableResolver: resolver,
-		tableParser:   NewParser(errWriter, root),
+	vm := &VM{
+		rootNS:         root,
+		errWriter:      errWriter,
+		tableResolver:  resolver,
+		tableParser:    NewParser(errWriter, root),
+		regionHandlers: make(map[RegionSpace]RegionHandler),
+		debugWriter:    errWriter,
+		debugLevel:     DebugLevelInfo,
 	}
+
+	vm.RegisterRegionHandler(RegionSpaceSystemMemory, defaultSystemMemoryHandler{})
+	vm.RegisterRegionHandler(RegionSpaceSystemIO, defaultSystemIOHandler{})
+
+	vm.globalLockMutex = &mutexEntity{namedEntity: namedEntity{name: `_GL_`}}
+	root.Append(vm.globalLockMutex)
+
+	return vm
 }
 
 // Init attempts to locate and parse the AML byte-code contained in the
@@ -219,7 +287,18 @@ func (vm *VM) execBlock(ctx *execContext, block ScopeEntity) *Error {
 
 	for instrIndex := 0; instrIndex < numInstr && ctx.ctrlFlow == ctrlFlowTypeNextOpcode; instrIndex++ {
 		instr := instrList[instrIndex]
-		if err := vm.jumpTable[instr.getOpcode()](ctx, instr); err != nil {
+
+		if vm.tracer != nil {
+			vm.tracer.BeforeOp(ctx, instr)
+		}
+
+		err := vm.jumpTable[instr.getOpcode()](ctx, instr)
+
+		if vm.tracer != nil {
+			vm.tracer.AfterOp(ctx, instr, err)
+		}
+
+		if err != nil {
 			return err
 		}
 	}
@@ -237,6 +316,7 @@ func defaultACPIScopes() ScopeEntity {
 	rootNS.Append(&scopeEntity{op: opScope, name: `_SB_`}) // System bus with all device objects
 	rootNS.Append(&scopeEntity{op: opScope, name: `_SI_`}) // System indicators
 	rootNS.Append(&scopeEntity{op: opScope, name: `_TZ_`}) // ACPI 1.0 thermal zone namespace
+	rootNS.Append(&debugEntity{namedEntity: namedEntity{name: `Debug`}}) // Reserved Debug object (§19.6.28)
 
 	return rootNS
 }
\ No newline at end of file